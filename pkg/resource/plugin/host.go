@@ -15,7 +15,10 @@
 package plugin
 
 import (
+	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/go-multierror"
@@ -37,15 +40,27 @@ type Host interface {
 	ServerAddr() string
 
 	// Log logs a message, including errors and warnings.  Messages can have a resource URN
-	// associated with them.  If no urn is provided, the message is global.
+	// associated with them.  If no urn is provided, the message is global.  It is a thin adapter over
+	// LogStructured, kept for callers that have only a severity and a message in hand.
 	Log(sev diag.Severity, urn resource.URN, msg string)
+	// LogStructured logs a full structured record -- including the plugin that produced it, a timestamp,
+	// and any fields the plugin attached -- to the host's diagnostics sink and, if one is configured, to the
+	// host's LogSink.
+	LogStructured(record LogRecord)
 
 	// Analyzer fetches the analyzer with a given name, possibly lazily allocating the plugins for it.  If an analyzer
 	// could not be found, or an error occurred while creating it, a non-nil error is returned.
 	Analyzer(nm tokens.QName) (Analyzer, error)
-	// Provider fetches the provider for a given package, lazily allocating it if necessary.  If a provider for this
-	// package could not be found, or an error occurs while creating it, a non-nil error is returned.
-	Provider(pkg tokens.Package, version *semver.Version) (Provider, error)
+	// Provider fetches the provider for a given package, lazily allocating it if necessary.  If version is
+	// non-nil, an already-running provider is reused as long as its own resolved version is greater than or
+	// equal to it; otherwise one is loaded and pinned to that version.  If versionRange is non-nil, it is
+	// resolved against all versions of the plugin installed on disk and the highest satisfying version is
+	// used.  Reuse is keyed by the provider's own resolved version rather than by what a particular caller
+	// requested, so two requests that are both satisfied by the same running provider share it, while two
+	// components in the same program can still bind to different versions of the same provider concurrently
+	// when their constraints actually require it.  If a provider for this package could not be found, or an
+	// error occurs while creating it, a non-nil error is returned.
+	Provider(pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (Provider, error)
 	// LanguageRuntime fetches the language runtime plugin for a given language, lazily allocating if necessary.  If
 	// an implementation of this language runtime wasn't found, on an error occurs, a non-nil error is returned.
 	LanguageRuntime(runtime string) (LanguageRuntime, error)
@@ -58,6 +73,10 @@ type Host interface {
 	// GetRequiredPlugins lists a full set of plugins that will be required by the given program.
 	GetRequiredPlugins(info ProgInfo, kinds Flags) ([]workspace.PluginInfo, error)
 
+	// PluginStatus reports the current health of the loaded plugin matching kind and name, as tracked by the
+	// host's supervisor.  Plugins that have never been loaded report PluginStatusUnknown.
+	PluginStatus(kind workspace.PluginKind, name string) PluginStatus
+
 	// Close reclaims any resources associated with the host.
 	Close() error
 }
@@ -68,18 +87,41 @@ type Events interface {
 	// OnPluginLoad is fired by the plugin host whenever a new plugin is successfully loaded.
 	// newPlugin is the plugin that was loaded.
 	OnPluginLoad(newPlugin workspace.PluginInfo) error
+	// OnPluginCrashed is fired whenever a supervised plugin's subprocess exits unexpectedly or fails a
+	// liveness check.
+	OnPluginCrashed(crashed PluginCrashed) error
+}
+
+// HostOptions bundles the optional, less frequently tweaked knobs accepted by NewDefaultHost, so that
+// additions here don't keep changing NewDefaultHost's signature.
+type HostOptions struct {
+	// RestartPolicy governs automatic restarts of crashed resource plugins.  The zero value is replaced
+	// with DefaultRestartPolicy.
+	RestartPolicy RestartPolicy
+	// LogSink, if non-nil, receives every structured log record produced by a plugin.
+	LogSink LogSink
+	// LoaderParallelism bounds the number of plugin subprocesses that may be started concurrently.  A
+	// non-positive value (including the zero value) falls back to defaultLoaderParallelism.
+	LoaderParallelism int
 }
 
 // NewDefaultHost implements the standard plugin logic, using the standard installation root to find them.
-func NewDefaultHost(ctx *Context, config ConfigSource, events Events) (Host, error) {
+func NewDefaultHost(ctx *Context, config ConfigSource, events Events, opts HostOptions) (Host, error) {
+	if opts.RestartPolicy == (RestartPolicy{}) {
+		opts.RestartPolicy = DefaultRestartPolicy
+	}
+
 	host := &defaultHost{
-		ctx:             ctx,
-		config:          config,
-		events:          events,
-		analyzerPlugins: make(map[tokens.QName]*analyzerPlugin),
-		languagePlugins: make(map[string]*languagePlugin),
-		resourcePlugins: make(map[tokens.Package]*resourcePlugin),
-		loadRequests:    make(chan pluginLoadRequest),
+		ctx:                 ctx,
+		config:              config,
+		events:              events,
+		restartPolicy:       opts.RestartPolicy,
+		logSink:             opts.LogSink,
+		analyzerPlugins:     make(map[tokens.QName]*analyzerPlugin),
+		languagePlugins:     make(map[string]*languagePlugin),
+		resourcePlugins:     make(map[resourceKey]*resourcePlugin),
+		resourceSupervisors: make(map[resourceKey]*pluginSupervisor),
+		loader:              newPluginLoader(opts.LoaderParallelism),
 	}
 
 	// Fire up a gRPC server to listen for requests.  This acts as a RPC interface that plugins can use
@@ -90,31 +132,41 @@ func NewDefaultHost(ctx *Context, config ConfigSource, events Events) (Host, err
 	}
 	host.server = svr
 
-	// Start a goroutine we'll use to satisfy load requests serially and avoid race conditions.
-	go func() {
-		for req := range host.loadRequests {
-			req.result <- req.load()
-		}
-	}()
-
 	return host, nil
 }
 
-type pluginLoadRequest struct {
-	load   func() error
-	result chan<- error
+type defaultHost struct {
+	ctx                 *Context                          // the shared context for this host.
+	config              ConfigSource                      // the source for provider configuration parameters.
+	events              Events                            // optional callbacks for plugin load events
+	restartPolicy       RestartPolicy                     // governs automatic restarts of crashed resource plugins.
+	logSink             LogSink                           // optional sink for structured plugin log records.
+	cacheMu             sync.Mutex                        // guards the plugin caches below from concurrent loads.
+	analyzerPlugins     map[tokens.QName]*analyzerPlugin  // a cache of analyzer plugins and their processes.
+	languagePlugins     map[string]*languagePlugin        // a cache of language plugins and their processes.
+	resourcePlugins     map[resourceKey]*resourcePlugin   // a cache of resource plugins, keyed by (pkg, resolved version).
+	resourceSupervisors map[resourceKey]*pluginSupervisor // crash supervisors for the resource plugins above.
+	plugins             []workspace.PluginInfo            // a list of plugins allocated by this host.
+	loader              *pluginLoader                     // coalesces and bounds concurrent plugin loads.
+	server              *hostServer                       // the server's RPC machinery.
 }
 
-type defaultHost struct {
-	ctx             *Context                           // the shared context for this host.
-	config          ConfigSource                       // the source for provider configuration parameters.
-	events          Events                             // optional callbacks for plugin load events
-	analyzerPlugins map[tokens.QName]*analyzerPlugin   // a cache of analyzer plugins and their processes.
-	languagePlugins map[string]*languagePlugin         // a cache of language plugins and their processes.
-	resourcePlugins map[tokens.Package]*resourcePlugin // a cache of resource plugins and their processes.
-	plugins         []workspace.PluginInfo             // a list of plugins allocated by this host.
-	loadRequests    chan pluginLoadRequest             // a channel used to satisfy plugin load requests.
-	server          *hostServer                        // the server's RPC machinery.
+// resourceKey identifies a cached resource provider plugin by package and the plugin's own resolved version
+// -- captured from its GetPluginInfo result once it is loaded, not from whatever version or range the caller
+// happened to request -- so that two components in the same program can bind to different versions of the
+// same provider concurrently, while a caller with a looser or absent constraint still reuses whatever
+// already-running plugin satisfies it.
+type resourceKey struct {
+	pkg     tokens.Package
+	version string // the plugin's own resolved version, or "" if it reported none.
+}
+
+// versionKey returns the cache key fragment for a (possibly nil) resolved version.
+func versionKey(version *semver.Version) string {
+	if version == nil {
+		return ""
+	}
+	return version.String()
 }
 
 type analyzerPlugin struct {
@@ -137,31 +189,54 @@ func (host *defaultHost) ServerAddr() string {
 }
 
 func (host *defaultHost) Log(sev diag.Severity, urn resource.URN, msg string) {
-	host.ctx.Diag.Logf(sev, diag.RawMessage(urn, msg))
+	host.LogStructured(LogRecord{Time: time.Now(), Sev: sev, URN: urn, Message: msg})
 }
 
-// loadPlugin sends an appropriate load request to the plugin loader and returns the loaded plugin (if any) and error.
-func (host *defaultHost) loadPlugin(load func() (interface{}, error)) (interface{}, error) {
-	var plugin interface{}
-
-	result := make(chan error)
-	host.loadRequests <- pluginLoadRequest{
-		load: func() error {
-			p, err := load()
-			plugin = p
-			return err
-		},
-		result: result,
+func (host *defaultHost) LogStructured(record LogRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	host.ctx.Diag.Logf(record.Sev, diag.RawMessage(record.URN, record.Message))
+	dispatchToLogSink(host.logSink, record)
+}
+
+// OnPluginLog logs one structured record from a plugin, stamping it with the plugin's identity before
+// dispatching it through the same LogStructured path used for host-originated messages, so the record's
+// Fields and Ephemeral reach the LogSink.  Nothing in this package calls it, and hostServer (the host's gRPC
+// server, outside this file) does not call it either.
+func (host *defaultHost) OnPluginLog(
+	pluginInfo workspace.PluginInfo, sev diag.Severity, urn resource.URN, msg string,
+	fields map[string]interface{}, ephemeral bool) {
+
+	host.LogStructured(LogRecord{
+		Plugin:    pluginInfo,
+		Time:      time.Now(),
+		Sev:       sev,
+		URN:       urn,
+		Message:   msg,
+		Fields:    fields,
+		Ephemeral: ephemeral,
+	})
+}
+
+// dispatchToLogSink sends record to sink if one is configured.  It is split out from LogStructured so the
+// dispatch behavior -- including the nil-sink no-op -- can be tested without constructing a full defaultHost.
+func dispatchToLogSink(sink LogSink, record LogRecord) {
+	if sink != nil {
+		sink.Log(record)
 	}
-	return plugin, <-result
 }
 
 func (host *defaultHost) Analyzer(name tokens.QName) (Analyzer, error) {
-	plugin, err := host.loadPlugin(func() (interface{}, error) {
+	key := pluginKey{kind: workspace.AnalyzerPlugin, name: string(name)}
+	plugin, err := host.loader.load(key, func() (interface{}, error) {
 		// First see if we already loaded this plugin.
-		if plug, has := host.analyzerPlugins[name]; has {
-			contract.Assert(plug != nil)
-			return plug.Plugin, nil
+		host.cacheMu.Lock()
+		cached, has := host.analyzerPlugins[name]
+		host.cacheMu.Unlock()
+		if has {
+			contract.Assert(cached != nil)
+			return cached.Plugin, nil
 		}
 
 		// If not, try to load and bind to a plugin.
@@ -173,8 +248,10 @@ func (host *defaultHost) Analyzer(name tokens.QName) (Analyzer, error) {
 			}
 
 			// Memoize the result.
+			host.cacheMu.Lock()
 			host.plugins = append(host.plugins, info)
 			host.analyzerPlugins[name] = &analyzerPlugin{Plugin: plug, Info: info}
+			host.cacheMu.Unlock()
 			if host.events != nil {
 				if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
 					return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
@@ -190,75 +267,100 @@ func (host *defaultHost) Analyzer(name tokens.QName) (Analyzer, error) {
 	return plugin.(Analyzer), nil
 }
 
-func (host *defaultHost) Provider(pkg tokens.Package, version *semver.Version) (Provider, error) {
-	plugin, err := host.loadPlugin(func() (interface{}, error) {
-		// First see if we already loaded this plugin.
-		if plug, has := host.resourcePlugins[pkg]; has {
-			contract.Assert(plug != nil)
-
-			// Make sure the versions match.
-			if version != nil {
-				if plug.Info.Version == nil {
-					return nil,
-						errors.Errorf("resource plugin version %s requested, but an unknown version was found",
-							version.String())
-				} else if !plug.Info.Version.GTE(*version) {
-					return nil,
-						errors.Errorf("resource plugin version %s requested, but version %s was found",
-							version.String(), plug.Info.Version.String())
-				}
-			}
+func (host *defaultHost) Provider(pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (Provider, error) {
+	// First, regardless of how this request is shaped, see if an already-loaded provider for pkg already
+	// satisfies it -- e.g. a caller asking for no constraint, or a loose range, should reuse whatever a
+	// differently-shaped earlier request already spun up, rather than forking a redundant subprocess.
+	if plug, has := host.matchingResourcePlugin(pkg, version, versionRange); has {
+		return plug.Plugin, nil
+	}
 
+	key := pluginKey{kind: workspace.ResourcePlugin, name: string(pkg), version: requestVersionKey(version, versionRange)}
+	plugin, err := host.loader.load(key, func() (interface{}, error) {
+		// Re-check now that we hold this request's load slot: another caller may have satisfied it (under a
+		// different pluginKey, e.g. a range that resolved to the same version) while we were waiting.
+		if plug, has := host.matchingResourcePlugin(pkg, version, versionRange); has {
 			return plug.Plugin, nil
 		}
 
-		// If not, try to load and bind to a plugin.
-		plug, err := NewProvider(host, host.ctx, pkg, version)
-		if err == nil && plug != nil {
-			info, infoerr := plug.GetPluginInfo()
-			if infoerr != nil {
-				return nil, infoerr
+		// If a range was given, resolve it against the plugins installed on disk and pin to the highest
+		// satisfying version, so that the plugin is loaded at a concrete version.
+		loadVersion := version
+		if versionRange != nil {
+			resolved, err := host.resolveProviderRange(pkg, *versionRange)
+			if err != nil {
+				return nil, err
 			}
+			loadVersion = resolved
+		}
 
-			// Warn if the plugin version was not what we expected
-			if version != nil && !cmdutil.IsTruthy(os.Getenv("PULUMI_DEV")) {
-				if info.Version == nil || !info.Version.GTE(*version) {
-					var v string
-					if info.Version != nil {
-						v = info.Version.String()
-					}
-					host.ctx.Diag.Warningf(
-						diag.Message("", /*urn*/
-							"resource plugin %s is expected to have version >=%s, but has %s; "+
-								"the wrong version may be on your path, or this may be a bug in the plugin"),
-						info.Name, version.String(), v)
+		// Try to load and bind to a plugin.
+		plug, err := NewProvider(host, host.ctx, pkg, loadVersion)
+		if err != nil || plug == nil {
+			return plug, err
+		}
+
+		info, infoerr := plug.GetPluginInfo()
+		if infoerr != nil {
+			return nil, infoerr
+		}
+
+		// Warn if the plugin version was not what we expected
+		if loadVersion != nil && !cmdutil.IsTruthy(os.Getenv("PULUMI_DEV")) {
+			if info.Version == nil || !info.Version.GTE(*loadVersion) {
+				var v string
+				if info.Version != nil {
+					v = info.Version.String()
 				}
+				host.ctx.Diag.Warningf(
+					diag.Message("", /*urn*/
+						"resource plugin %s is expected to have version >=%s, but has %s; "+
+							"the wrong version may be on your path, or this may be a bug in the plugin"),
+					info.Name, loadVersion.String(), v)
 			}
+		}
 
-			// Configure the provider. If no configuration source is present, assume no configuration. We do this here
-			// because resource providers must be configured exactly once before any method besides Configure is called.
-			providerConfig := make(map[config.Key]string)
-			if host.config != nil {
-				providerConfig, err = host.config.GetPackageConfig(pkg)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to fetch configuration for pkg '%v' resource provider", pkg)
-				}
+		// Configure the provider. If no configuration source is present, assume no configuration. We do this here
+		// because resource providers must be configured exactly once before any method besides Configure is called.
+		providerConfig := make(map[config.Key]string)
+		if host.config != nil {
+			providerConfig, err = host.config.GetPackageConfig(pkg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch configuration for pkg '%v' resource provider", pkg)
 			}
-			if err = plug.Configure(providerConfig); err != nil {
-				return nil, errors.Wrapf(err, "failed to configure pkg '%v' resource provider", pkg)
+		}
+		if err = plug.Configure(providerConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to configure pkg '%v' resource provider", pkg)
+		}
+
+		// Now that we know the plugin's own resolved version, key the cache by it rather than by whatever
+		// version or range this particular caller requested -- two different requests that resolve to the
+		// same running plugin must land on the same cache entry.
+		rkey := resourceKey{pkg: pkg, version: versionKey(info.Version)}
+
+		host.cacheMu.Lock()
+		if existing, has := host.resourcePlugins[rkey]; has {
+			// Lost a race with a concurrently loaded, differently-requested plugin that resolved to the same
+			// version; reuse the winner and close the redundant one we just started.
+			host.cacheMu.Unlock()
+			if closeerr := plug.Close(); closeerr != nil {
+				logging.Infof("error closing redundant resource plugin %s during dedup; ignoring: %v", pkg, closeerr)
 			}
+			return existing.Plugin, nil
+		}
 
-			// Memoize the result.
-			host.plugins = append(host.plugins, info)
-			host.resourcePlugins[pkg] = &resourcePlugin{Plugin: plug, Info: info}
-			if host.events != nil {
-				if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
-					return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
-				}
+		// Memoize the result and start watching it for crashes.
+		host.plugins = append(host.plugins, info)
+		host.resourcePlugins[rkey] = &resourcePlugin{Plugin: plug, Info: info}
+		host.resourceSupervisors[rkey] = host.newPluginSupervisor(rkey, providerConfig)
+		host.cacheMu.Unlock()
+		if host.events != nil {
+			if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
+				return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
 			}
 		}
 
-		return plug, err
+		return plug, nil
 	})
 	if plugin == nil || err != nil {
 		return nil, err
@@ -266,12 +368,97 @@ func (host *defaultHost) Provider(pkg tokens.Package, version *semver.Version) (
 	return plugin.(Provider), nil
 }
 
+// matchingResourcePlugin scans the already-loaded resource plugins for pkg and returns one whose resolved
+// version satisfies the given constraint, if any.  A nil version and nil versionRange match any loaded
+// plugin for pkg.
+func (host *defaultHost) matchingResourcePlugin(
+	pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (*resourcePlugin, bool) {
+
+	host.cacheMu.Lock()
+	defer host.cacheMu.Unlock()
+
+	for rkey, plug := range host.resourcePlugins {
+		if rkey.pkg != pkg {
+			continue
+		}
+		if version != nil {
+			if plug.Info.Version == nil || !plug.Info.Version.GTE(*version) {
+				continue
+			}
+		}
+		if versionRange != nil {
+			if plug.Info.Version == nil || !(*versionRange)(*plug.Info.Version) {
+				continue
+			}
+		}
+		return plug, true
+	}
+	return nil, false
+}
+
+// requestVersionKey returns the pluginKey fragment for a provider request, so that concurrent callers asking
+// for the exact same version coalesce onto a single load via the pluginLoader.  It is distinct from
+// resourceKey.version, which is keyed by the plugin's own resolved version once loaded.
+//
+// semver.Range is a predicate function with no stable string form, so a range request is keyed by the
+// identity of the *semver.Range pointer: callers that share one Range value (the common case -- a range
+// parsed once and passed to every call site that needs it) coalesce as expected, while two distinct Range
+// values are never mistaken for the same request even if they happen to accept the same versions.
+func requestVersionKey(version *semver.Version, versionRange *semver.Range) string {
+	switch {
+	case versionRange != nil:
+		return fmt.Sprintf("range:%p", versionRange)
+	case version != nil:
+		return version.String()
+	default:
+		return ""
+	}
+}
+
+// resolveProviderRange resolves a semver.Range against all versions of pkg installed on disk and returns the
+// highest satisfying version.  It returns an error if no installed version satisfies the range.
+func (host *defaultHost) resolveProviderRange(pkg tokens.Package, r semver.Range) (*semver.Version, error) {
+	installed, err := workspace.GetPlugins()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to enumerate installed plugins while resolving %s", pkg)
+	}
+
+	best := selectBestCandidate(installed, pkg, r)
+	if best == nil {
+		return nil, errors.Errorf("no installed version of resource plugin %s satisfies the requested version range", pkg)
+	}
+	return best, nil
+}
+
+// selectBestCandidate returns the highest version among installed resource plugins named pkg that satisfies
+// r, or nil if none do.  It is a pure function of its arguments so resolveProviderRange's candidate-selection
+// logic can be tested without touching disk.
+func selectBestCandidate(installed []workspace.PluginInfo, pkg tokens.Package, r semver.Range) *semver.Version {
+	var best *semver.Version
+	for _, candidate := range installed {
+		if candidate.Kind != workspace.ResourcePlugin ||
+			tokens.Package(candidate.Name) != pkg ||
+			candidate.Version == nil ||
+			!r(*candidate.Version) {
+			continue
+		}
+		if best == nil || candidate.Version.GT(*best) {
+			best = candidate.Version
+		}
+	}
+	return best
+}
+
 func (host *defaultHost) LanguageRuntime(runtime string) (LanguageRuntime, error) {
-	plugin, err := host.loadPlugin(func() (interface{}, error) {
+	key := pluginKey{kind: workspace.LanguagePlugin, name: runtime}
+	plugin, err := host.loader.load(key, func() (interface{}, error) {
 		// First see if we already loaded this plugin.
-		if plug, has := host.languagePlugins[runtime]; has {
-			contract.Assert(plug != nil)
-			return plug.Plugin, nil
+		host.cacheMu.Lock()
+		cached, has := host.languagePlugins[runtime]
+		host.cacheMu.Unlock()
+		if has {
+			contract.Assert(cached != nil)
+			return cached.Plugin, nil
 		}
 
 		// If not, allocate a new one.
@@ -283,8 +470,10 @@ func (host *defaultHost) LanguageRuntime(runtime string) (LanguageRuntime, error
 			}
 
 			// Memoize the result.
+			host.cacheMu.Lock()
 			host.plugins = append(host.plugins, info)
 			host.languagePlugins[runtime] = &languagePlugin{Plugin: plug, Info: info}
+			host.cacheMu.Unlock()
 			if host.events != nil {
 				if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
 					return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
@@ -301,41 +490,64 @@ func (host *defaultHost) LanguageRuntime(runtime string) (LanguageRuntime, error
 }
 
 func (host *defaultHost) ListPlugins() []workspace.PluginInfo {
+	host.cacheMu.Lock()
+	defer host.cacheMu.Unlock()
 	return host.plugins
 }
 
 // EnsurePlugins ensures all plugins in the given array are loaded and ready to use.  If any plugins are missing,
-// and/or there are errors loading one or more plugins, a non-nil error is returned.
+// and/or there are errors loading one or more plugins, a non-nil error is returned.  Independent plugins are
+// loaded in parallel; loads for the same plugin are coalesced by the host's pluginLoader.
 func (host *defaultHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds Flags) error {
-	// Use a multieerror to track failures so we can return one big list of all failures at the end.
+	// Use a multierror to track failures so we can return one big list of all failures at the end.  Loads run
+	// concurrently, so guard result with a mutex.
+	var mu sync.Mutex
 	var result error
+	addErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result = multierror.Append(result, err)
+	}
+
+	var wg sync.WaitGroup
 	for _, plugin := range plugins {
+		plugin := plugin // capture the loop variable for the goroutines below.
 		switch plugin.Kind {
 		case workspace.AnalyzerPlugin:
 			if kinds&AnalyzerPlugins != 0 {
-				if _, err := host.Analyzer(tokens.QName(plugin.Name)); err != nil {
-					result = multierror.Append(result,
-						errors.Wrapf(err, "failed to load analyzer plugin %s", plugin.Name))
-				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := host.Analyzer(tokens.QName(plugin.Name)); err != nil {
+						addErr(errors.Wrapf(err, "failed to load analyzer plugin %s", plugin.Name))
+					}
+				}()
 			}
 		case workspace.LanguagePlugin:
 			if kinds&LanguagePlugins != 0 {
-				if _, err := host.LanguageRuntime(plugin.Name); err != nil {
-					result = multierror.Append(result,
-						errors.Wrapf(err, "failed to load language plugin %s", plugin.Name))
-				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := host.LanguageRuntime(plugin.Name); err != nil {
+						addErr(errors.Wrapf(err, "failed to load language plugin %s", plugin.Name))
+					}
+				}()
 			}
 		case workspace.ResourcePlugin:
 			if kinds&ResourcePlugins != 0 {
-				if _, err := host.Provider(tokens.Package(plugin.Name), plugin.Version); err != nil {
-					result = multierror.Append(result,
-						errors.Wrapf(err, "failed to load resource plugin %s", plugin.Name))
-				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := host.Provider(tokens.Package(plugin.Name), plugin.Version, plugin.VersionRange); err != nil {
+						addErr(errors.Wrapf(err, "failed to load resource plugin %s", plugin.Name))
+					}
+				}()
 			}
 		default:
 			contract.Failf("unexpected plugin kind: %s", plugin.Kind)
 		}
 	}
+	wg.Wait()
 
 	return result
 }
@@ -388,31 +600,42 @@ func (host *defaultHost) GetRequiredPlugins(info ProgInfo, kinds Flags) ([]works
 }
 
 func (host *defaultHost) Close() error {
+	// Snapshot and empty out all the caches under cacheMu, rather than ranging over them live: background
+	// goroutines from in-flight EnsurePlugins/Provider/Analyzer/LanguageRuntime calls and plugin restarts all
+	// mutate these same maps concurrently, so an unguarded range here can race a concurrent map write.
+	host.cacheMu.Lock()
+	supervisors := host.resourceSupervisors
+	analyzerPlugins := host.analyzerPlugins
+	resourcePlugins := host.resourcePlugins
+	languagePlugins := host.languagePlugins
+	host.analyzerPlugins = make(map[tokens.QName]*analyzerPlugin)
+	host.languagePlugins = make(map[string]*languagePlugin)
+	host.resourcePlugins = make(map[resourceKey]*resourcePlugin)
+	host.resourceSupervisors = make(map[resourceKey]*pluginSupervisor)
+	host.cacheMu.Unlock()
+
+	// Stop supervising resource plugins before closing them, so a clean shutdown is never mistaken for a crash.
+	for _, sup := range supervisors {
+		sup.Close()
+	}
+
 	// Close all plugins.
-	for _, plug := range host.analyzerPlugins {
+	for _, plug := range analyzerPlugins {
 		if err := plug.Plugin.Close(); err != nil {
 			logging.Infof("Error closing '%s' analyzer plugin during shutdown; ignoring: %v", plug.Info.Name, err)
 		}
 	}
-	for _, plug := range host.resourcePlugins {
+	for _, plug := range resourcePlugins {
 		if err := plug.Plugin.Close(); err != nil {
 			logging.Infof("Error closing '%s' resource plugin during shutdown; ignoring: %v", plug.Info.Name, err)
 		}
 	}
-	for _, plug := range host.languagePlugins {
+	for _, plug := range languagePlugins {
 		if err := plug.Plugin.Close(); err != nil {
 			logging.Infof("Error closing '%s' language plugin during shutdown; ignoring: %v", plug.Info.Name, err)
 		}
 	}
 
-	// Empty out all maps.
-	host.analyzerPlugins = make(map[tokens.QName]*analyzerPlugin)
-	host.languagePlugins = make(map[string]*languagePlugin)
-	host.resourcePlugins = make(map[tokens.Package]*resourcePlugin)
-
-	// Shut down the plugin loader.
-	close(host.loadRequests)
-
 	// Finally, shut down the host's gRPC server.
 	return host.server.Cancel()
 }
@@ -0,0 +1,263 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// DefaultDaemonIdleTimeout is how long the daemon keeps a workspace's plugins around without any RPC
+// activity before evicting them.
+const DefaultDaemonIdleTimeout = 30 * time.Minute
+
+// DaemonSocketPath returns the single well-known socket the plugin daemon listens on.  One daemon process
+// serves every workspace on the machine, each isolated from the others by the isolation key (see
+// IsolationKey) that RemoteHost attaches to every RPC -- not by the socket path itself.
+func DaemonSocketPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "resolving user home directory")
+	}
+	return filepath.Join(home, ".pulumi", "daemon", "daemon.sock"), nil
+}
+
+// IsolationKey derives the isolation key a RemoteHost presents to the daemon for a given workspace root, so
+// that the daemon can route it to a per-workspace Host (see daemonServer) without two different projects ever
+// sharing one -- a misconfigured provider loaded for one workspace can't leak into another.
+func IsolationKey(workspaceRoot string) (string, error) {
+	abs, err := filepath.Abs(workspaceRoot)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving workspace root %s", workspaceRoot)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// daemonConn is the subset of a generated plugin-daemon gRPC client that RemoteHost needs.  It is satisfied
+// by the pulumirpc daemon client stub; that generated code, and the `pulumi plugin daemon` subcommand that
+// launches the daemon process and dials it, live in pkg/cmd and are outside this package's scope.  Defining
+// the boundary as an interface here keeps RemoteHost's proxying logic reviewable and testable on its own.
+// Every method is scoped to the isolation key the connection was established with -- attaching it (e.g. as
+// gRPC metadata on each call) is also part of that out-of-scope client implementation.
+type daemonConn interface {
+	Analyzer(name tokens.QName) (Analyzer, error)
+	Provider(pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (Provider, error)
+	LanguageRuntime(runtime string) (LanguageRuntime, error)
+	ListPlugins() ([]workspace.PluginInfo, error)
+	EnsurePlugins(plugins []workspace.PluginInfo, kinds Flags) error
+	GetRequiredPlugins(info ProgInfo, kinds Flags) ([]workspace.PluginInfo, error)
+	PluginStatus(kind workspace.PluginKind, name string) PluginStatus
+	// SubscribeLogs registers for every structured log record produced by this isolation key's plugins from
+	// this point forward, relaying each onto records until unsubscribe is called.  Without this, a LogSink
+	// configured on the CLI side would never see plugin log output once the daemon -- not the CLI process --
+	// is the one actually owning the plugin subprocesses.
+	SubscribeLogs(records chan<- LogRecord) (unsubscribe func(), err error)
+	Close() error
+}
+
+// RemoteHost implements Host by proxying every call to a long-lived plugin daemon process instead of
+// spawning and owning plugin subprocesses itself.  This lets provider startup cost be paid once per daemon
+// lifetime rather than once per CLI invocation.
+type RemoteHost struct {
+	ctx             *Context
+	conn            daemonConn
+	logSink         LogSink
+	unsubscribeLogs func()
+}
+
+// NewRemoteHost wraps an established connection to a plugin daemon as a Host.  Callers are expected to have
+// already dialed DaemonSocketPath and identified themselves with the IsolationKey for their workspace, so the
+// daemon can route the connection to the right per-workspace Host.  If logSink is non-nil, it is subscribed
+// to receive every structured log record the daemon's plugins produce for this isolation key, so that
+// plugins running inside the daemon aren't invisible to a LogSink configured on the CLI side.
+func NewRemoteHost(ctx *Context, conn daemonConn, logSink LogSink) (Host, error) {
+	h := &RemoteHost{ctx: ctx, conn: conn, logSink: logSink}
+
+	if logSink != nil {
+		records := make(chan LogRecord)
+		unsubscribe, err := conn.SubscribeLogs(records)
+		if err != nil {
+			return nil, errors.Wrap(err, "subscribing to daemon plugin logs")
+		}
+		h.unsubscribeLogs = unsubscribe
+		go func() {
+			for record := range records {
+				dispatchToLogSink(h.logSink, record)
+			}
+		}()
+	}
+
+	return h, nil
+}
+
+// ServerAddr returns "": a RemoteHost has no RPC server of its own for plugins to phone home to; that
+// server lives in the daemon process.
+func (h *RemoteHost) ServerAddr() string {
+	return ""
+}
+
+func (h *RemoteHost) Log(sev diag.Severity, urn resource.URN, msg string) {
+	h.LogStructured(LogRecord{Time: time.Now(), Sev: sev, URN: urn, Message: msg})
+}
+
+func (h *RemoteHost) LogStructured(record LogRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	h.ctx.Diag.Logf(record.Sev, diag.RawMessage(record.URN, record.Message))
+	dispatchToLogSink(h.logSink, record)
+}
+
+func (h *RemoteHost) Analyzer(name tokens.QName) (Analyzer, error) {
+	return h.conn.Analyzer(name)
+}
+
+func (h *RemoteHost) Provider(pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (Provider, error) {
+	return h.conn.Provider(pkg, version, versionRange)
+}
+
+func (h *RemoteHost) LanguageRuntime(runtime string) (LanguageRuntime, error) {
+	return h.conn.LanguageRuntime(runtime)
+}
+
+func (h *RemoteHost) ListPlugins() []workspace.PluginInfo {
+	plugins, err := h.conn.ListPlugins()
+	if err != nil {
+		logging.Infof("failed to list plugins from daemon; ignoring: %v", err)
+		return nil
+	}
+	return plugins
+}
+
+func (h *RemoteHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds Flags) error {
+	return h.conn.EnsurePlugins(plugins, kinds)
+}
+
+func (h *RemoteHost) GetRequiredPlugins(info ProgInfo, kinds Flags) ([]workspace.PluginInfo, error) {
+	return h.conn.GetRequiredPlugins(info, kinds)
+}
+
+func (h *RemoteHost) PluginStatus(kind workspace.PluginKind, name string) PluginStatus {
+	return h.conn.PluginStatus(kind, name)
+}
+
+// Close closes this Host's connection to the daemon.  It does not tear down the daemon's plugins: those are
+// owned by the daemon process and outlive any single CLI invocation, subject to the daemon's idle timeout.
+func (h *RemoteHost) Close() error {
+	if h.unsubscribeLogs != nil {
+		h.unsubscribeLogs()
+	}
+	return h.conn.Close()
+}
+
+// daemonWorkspace tracks one isolation key's Host and when it was last used, so the daemon can evict it
+// after its idleTimeout of inactivity.
+type daemonWorkspace struct {
+	host       Host
+	lastActive time.Time
+}
+
+// daemonServer is the daemon-side process that owns plugin subprocesses across CLI invocations.  A single
+// daemonServer serves every isolation key concurrently, each key getting its own Host so that a
+// misconfigured provider loaded under one workspace can never affect another.  The gRPC listener that
+// accepts RemoteHost connections and the `pulumi plugin daemon` subcommand that starts this process are
+// part of the CLI (pkg/cmd) and outside this package's scope; daemonServer is the lifecycle core they embed.
+type daemonServer struct {
+	idleTimeout time.Duration
+
+	mu         sync.Mutex
+	workspaces map[string]*daemonWorkspace
+}
+
+// newDaemonServer creates a daemonServer.  A non-positive idleTimeout falls back to
+// DefaultDaemonIdleTimeout.
+func newDaemonServer(idleTimeout time.Duration) *daemonServer {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultDaemonIdleTimeout
+	}
+	d := &daemonServer{idleTimeout: idleTimeout, workspaces: make(map[string]*daemonWorkspace)}
+	go d.evictIdleLoop()
+	return d
+}
+
+// hostFor returns the Host for isolationKey, creating one via newHost if this is the first request for that
+// key.  Every call refreshes the key's last-activity time, postponing its eviction.
+func (d *daemonServer) hostFor(isolationKey string, newHost func() (Host, error)) (Host, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ws, has := d.workspaces[isolationKey]
+	if !has {
+		host, err := newHost()
+		if err != nil {
+			return nil, err
+		}
+		ws = &daemonWorkspace{host: host}
+		d.workspaces[isolationKey] = ws
+	}
+	ws.lastActive = time.Now()
+	return ws.host, nil
+}
+
+// Idle reports whether the daemon currently has no workspaces loaded, which the owning `pulumi plugin
+// daemon` process can poll to decide when it is safe to exit.
+func (d *daemonServer) Idle() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.workspaces) == 0
+}
+
+// evictIdleLoop periodically closes and forgets any workspace that has had no activity for idleTimeout or
+// longer.
+func (d *daemonServer) evictIdleLoop() {
+	ticker := time.NewTicker(d.idleTimeout / 4)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		d.evictIdleAsOf(now)
+	}
+}
+
+// evictIdleAsOf closes and forgets any workspace whose lastActive is idleTimeout or more before now.  It is
+// split out from evictIdleLoop so tests can drive eviction deterministically instead of waiting on a real
+// ticker.
+func (d *daemonServer) evictIdleAsOf(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, ws := range d.workspaces {
+		if now.Sub(ws.lastActive) < d.idleTimeout {
+			continue
+		}
+		if err := ws.host.Close(); err != nil {
+			logging.Infof("error closing idle daemon workspace %s; ignoring: %v", key, err)
+		}
+		delete(d.workspaces, key)
+	}
+}
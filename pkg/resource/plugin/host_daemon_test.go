@@ -0,0 +1,132 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// fakeHost is a minimal Host test double, so daemonServer's isolation-key lookup and idle eviction can be
+// tested without standing up a real defaultHost.
+type fakeHost struct {
+	closed bool
+}
+
+func (f *fakeHost) ServerAddr() string                                  { return "" }
+func (f *fakeHost) Log(sev diag.Severity, urn resource.URN, msg string) {}
+func (f *fakeHost) LogStructured(record LogRecord)                      {}
+func (f *fakeHost) Analyzer(nm tokens.QName) (Analyzer, error)          { return nil, nil }
+
+func (f *fakeHost) Provider(
+	pkg tokens.Package, version *semver.Version, versionRange *semver.Range) (Provider, error) {
+	return nil, nil
+}
+
+func (f *fakeHost) LanguageRuntime(runtime string) (LanguageRuntime, error)         { return nil, nil }
+func (f *fakeHost) ListPlugins() []workspace.PluginInfo                             { return nil }
+func (f *fakeHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds Flags) error { return nil }
+
+func (f *fakeHost) GetRequiredPlugins(info ProgInfo, kinds Flags) ([]workspace.PluginInfo, error) {
+	return nil, nil
+}
+func (f *fakeHost) PluginStatus(kind workspace.PluginKind, name string) PluginStatus {
+	return PluginStatusUnknown
+}
+func (f *fakeHost) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestIsolationKeyIsStableAndDistinguishesWorkspaces(t *testing.T) {
+	k1, err := IsolationKey("/home/user/project-a")
+	assert.NoError(t, err)
+	k1Again, err := IsolationKey("/home/user/project-a")
+	assert.NoError(t, err)
+	assert.Equal(t, k1, k1Again)
+
+	k2, err := IsolationKey("/home/user/project-b")
+	assert.NoError(t, err)
+	assert.NotEqual(t, k1, k2)
+}
+
+func TestDaemonSocketPathDoesNotVaryByWorkspace(t *testing.T) {
+	// Unlike IsolationKey, the daemon listens on one socket regardless of which workspace is calling --
+	// isolation is handled per-RPC, not per-socket.
+	p1, err := DaemonSocketPath()
+	assert.NoError(t, err)
+	p2, err := DaemonSocketPath()
+	assert.NoError(t, err)
+	assert.Equal(t, p1, p2)
+}
+
+func TestHostForReusesTheSameHostForTheSameIsolationKey(t *testing.T) {
+	d := &daemonServer{idleTimeout: time.Hour, workspaces: make(map[string]*daemonWorkspace)}
+
+	var created int
+	newHost := func() (Host, error) {
+		created++
+		return &fakeHost{}, nil
+	}
+
+	h1, err := d.hostFor("key-a", newHost)
+	assert.NoError(t, err)
+	h2, err := d.hostFor("key-a", newHost)
+	assert.NoError(t, err)
+
+	assert.Same(t, h1, h2)
+	assert.Equal(t, 1, created)
+}
+
+func TestHostForGivesDifferentIsolationKeysDifferentHosts(t *testing.T) {
+	d := &daemonServer{idleTimeout: time.Hour, workspaces: make(map[string]*daemonWorkspace)}
+
+	newHost := func() (Host, error) { return &fakeHost{}, nil }
+
+	h1, err := d.hostFor("key-a", newHost)
+	assert.NoError(t, err)
+	h2, err := d.hostFor("key-b", newHost)
+	assert.NoError(t, err)
+
+	assert.NotSame(t, h1, h2)
+}
+
+func TestEvictIdleAsOfClosesAndForgetsOnlyStaleWorkspaces(t *testing.T) {
+	d := &daemonServer{idleTimeout: time.Minute, workspaces: make(map[string]*daemonWorkspace)}
+
+	stale := &fakeHost{}
+	fresh := &fakeHost{}
+	now := time.Now()
+	d.workspaces["stale"] = &daemonWorkspace{host: stale, lastActive: now.Add(-2 * time.Minute)}
+	d.workspaces["fresh"] = &daemonWorkspace{host: fresh, lastActive: now}
+
+	d.evictIdleAsOf(now)
+
+	assert.True(t, stale.closed, "a workspace idle longer than idleTimeout should be closed")
+	assert.False(t, fresh.closed, "a recently active workspace should not be closed")
+
+	_, staleStillPresent := d.workspaces["stale"]
+	_, freshStillPresent := d.workspaces["fresh"]
+	assert.False(t, staleStillPresent)
+	assert.True(t, freshStillPresent)
+}
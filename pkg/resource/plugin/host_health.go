@@ -0,0 +1,295 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// PluginStatus describes the current health of a loaded plugin, as tracked by the host's supervisor.
+type PluginStatus int
+
+const (
+	// PluginStatusUnknown is returned for plugins the host has no supervision information for, including
+	// ones that have never been loaded.
+	PluginStatusUnknown PluginStatus = iota
+	// PluginStatusRunning indicates the plugin's subprocess is alive and its last liveness check succeeded.
+	PluginStatusRunning
+	// PluginStatusRestarting indicates the plugin crashed and the host is attempting to restart it.
+	PluginStatusRestarting
+	// PluginStatusCrashed indicates the plugin crashed and either restarts are disabled or all restart
+	// attempts permitted by the host's RestartPolicy have been exhausted.
+	PluginStatusCrashed
+)
+
+func (s PluginStatus) String() string {
+	switch s {
+	case PluginStatusRunning:
+		return "running"
+	case PluginStatusRestarting:
+		return "restarting"
+	case PluginStatusCrashed:
+		return "crashed"
+	default:
+		return "unknown"
+	}
+}
+
+// RestartPolicy controls how the host reacts when a supervised plugin crashes.
+type RestartPolicy struct {
+	// MaxAttempts is the maximum number of times a crashed plugin will be restarted before the host gives up
+	// and leaves it in the PluginStatusCrashed state.  A value of 0 disables automatic restarts entirely.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first restart attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff applied between subsequent restart attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRestartPolicy is used by NewDefaultHost when no policy is supplied.
+var DefaultRestartPolicy = RestartPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// livenessPingInterval is how often a supervisor issues a lightweight GetPluginInfo RPC to confirm a plugin
+// is still responsive, independent of whether its subprocess has exited.
+const livenessPingInterval = 30 * time.Second
+
+// PluginCrashed is delivered through Events.OnPluginCrashed whenever a supervised plugin's subprocess exits
+// unexpectedly or fails a liveness check.
+type PluginCrashed struct {
+	// Info identifies the plugin that crashed.
+	Info workspace.PluginInfo
+	// Err is the error observed -- either the subprocess's exit error or the liveness check's failure.
+	Err error
+	// WillRestart is true if the host's RestartPolicy permits another restart attempt.
+	WillRestart bool
+}
+
+// monitoredPlugin is implemented by plugin bindings that expose enough of their subprocess lifecycle for the
+// host to watch for the subprocess's exit.  Bindings that don't implement it (e.g. in-process test doubles)
+// have no exit to watch and are supervised by GetPluginInfo liveness pings alone.
+type monitoredPlugin interface {
+	// Done returns a channel that receives the subprocess's exit error (nil for a clean exit) and is then
+	// closed.  Done must return the same channel on every call.
+	Done() <-chan error
+}
+
+// pluginSupervisor watches a single loaded resource provider for crashes and, per the host's RestartPolicy,
+// transparently restarts and reconfigures it.
+type pluginSupervisor struct {
+	host   *defaultHost
+	rkey   resourceKey
+	pkg    tokens.Package
+	config map[config.Key]string // the provider configuration to replay after a restart.
+
+	mu     sync.Mutex
+	status PluginStatus
+	stop   chan struct{}
+}
+
+// newPluginSupervisor starts supervising the resource plugin cached under rkey, and returns immediately; all
+// supervision happens on a background goroutine.
+func (host *defaultHost) newPluginSupervisor(rkey resourceKey, providerConfig map[config.Key]string) *pluginSupervisor {
+	sup := &pluginSupervisor{
+		host:   host,
+		rkey:   rkey,
+		pkg:    rkey.pkg,
+		config: providerConfig,
+		status: PluginStatusRunning,
+		stop:   make(chan struct{}),
+	}
+	go sup.run()
+	return sup
+}
+
+func (sup *pluginSupervisor) setStatus(status PluginStatus) {
+	sup.mu.Lock()
+	sup.status = status
+	sup.mu.Unlock()
+}
+
+func (sup *pluginSupervisor) getStatus() PluginStatus {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	return sup.status
+}
+
+// run watches the supervised plugin until either it is closed by the host or it crashes and every permitted
+// restart attempt has been exhausted.
+func (sup *pluginSupervisor) run() {
+	policy := sup.host.restartPolicy
+	backoff := policy.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		host := sup.host
+
+		host.cacheMu.Lock()
+		plug, has := host.resourcePlugins[sup.rkey]
+		host.cacheMu.Unlock()
+		if !has {
+			return
+		}
+
+		crashErr := sup.watch(plug.Plugin, livenessPingInterval)
+		if crashErr == errSupervisorStopped {
+			// The host is shutting down; nothing further to do.
+			return
+		}
+
+		willRestart := policy.MaxAttempts > 0 && attempt < policy.MaxAttempts
+		sup.setStatus(PluginStatusRestarting)
+		if !willRestart {
+			sup.setStatus(PluginStatusCrashed)
+		}
+
+		logging.Infof("resource plugin %s crashed: %v (will restart: %v)", sup.pkg, crashErr, willRestart)
+		if host.events != nil {
+			if err := host.events.OnPluginCrashed(PluginCrashed{
+				Info:        plug.Info,
+				Err:         crashErr,
+				WillRestart: willRestart,
+			}); err != nil {
+				logging.Infof("plugin crash callback for %s returned an error; ignoring: %v", sup.pkg, err)
+			}
+		}
+
+		if !willRestart {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-sup.stop:
+			return
+		}
+		if backoff *= 2; backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+
+		if err := sup.restart(); err != nil {
+			logging.Infof("failed to restart resource plugin %s; ignoring: %v", sup.pkg, err)
+			continue
+		}
+		sup.setStatus(PluginStatusRunning)
+	}
+}
+
+// errSupervisorStopped is returned by watch when the supervisor was stopped by the host, as opposed to the
+// plugin having actually crashed.
+var errSupervisorStopped = errors.New("plugin supervisor stopped")
+
+// livenessChecker is the subset of Provider that watch needs to issue its periodic liveness ping.  Narrowing
+// to this interface (rather than taking a full Provider) lets tests exercise watch's crash-detection logic
+// against a lightweight fake instead of a real provider plugin.
+type livenessChecker interface {
+	GetPluginInfo() (workspace.PluginInfo, error)
+}
+
+// watch blocks until the supervised plugin exits, fails a liveness check, or the supervisor is stopped, in
+// which case it returns errSupervisorStopped.  pingInterval is a parameter, rather than always
+// livenessPingInterval, so tests can drive many ping cycles without waiting on a real 30-second ticker.
+func (sup *pluginSupervisor) watch(plug livenessChecker, pingInterval time.Duration) error {
+	var done <-chan error
+	if mon, ok := plug.(monitoredPlugin); ok {
+		done = mon.Done()
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			if _, err := plug.GetPluginInfo(); err != nil {
+				return err
+			}
+		case <-sup.stop:
+			return errSupervisorStopped
+		}
+	}
+}
+
+// restart re-allocates the resource plugin and re-invokes Configure with the cached provider configuration,
+// then swaps it into the host's cache in place of the crashed one.
+func (sup *pluginSupervisor) restart() error {
+	host := sup.host
+
+	var version *semver.Version
+	if sup.rkey.version != "" {
+		v, err := semver.ParseTolerant(sup.rkey.version)
+		if err != nil {
+			return err
+		}
+		version = &v
+	}
+
+	plug, err := NewProvider(host, host.ctx, sup.pkg, version)
+	if err != nil {
+		return err
+	}
+	if err := plug.Configure(sup.config); err != nil {
+		return err
+	}
+	info, err := plug.GetPluginInfo()
+	if err != nil {
+		return err
+	}
+
+	host.cacheMu.Lock()
+	host.plugins = append(host.plugins, info)
+	host.resourcePlugins[sup.rkey] = &resourcePlugin{Plugin: plug, Info: info}
+	host.cacheMu.Unlock()
+	return nil
+}
+
+// Close stops the supervisor's background goroutine without closing the plugin itself -- the plugin is
+// closed by the host's own Close logic.
+func (sup *pluginSupervisor) Close() {
+	close(sup.stop)
+}
+
+// PluginStatus returns the current health of the loaded plugin matching kind and name, across all versions
+// cached under that name.  If more than one version is cached, the worst-case status is returned.  Only
+// resource plugins are supervised: analyzer and language plugins, and plugins that have never been loaded,
+// report PluginStatusUnknown.
+func (host *defaultHost) PluginStatus(kind workspace.PluginKind, name string) PluginStatus {
+	host.cacheMu.Lock()
+	defer host.cacheMu.Unlock()
+
+	worst := PluginStatusUnknown
+	for rkey, sup := range host.resourceSupervisors {
+		if kind != workspace.ResourcePlugin || string(rkey.pkg) != name {
+			continue
+		}
+		if status := sup.getStatus(); status > worst {
+			worst = status
+		}
+	}
+	return worst
+}
@@ -0,0 +1,99 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// fakeMonitoredPlugin is a livenessChecker and monitoredPlugin test double that lets tests trigger a
+// subprocess exit or a liveness-check failure on demand, without spawning a real plugin process.
+type fakeMonitoredPlugin struct {
+	done      chan error
+	infoErr   error
+	infoCalls int
+}
+
+func newFakeMonitoredPlugin() *fakeMonitoredPlugin {
+	return &fakeMonitoredPlugin{done: make(chan error, 1)}
+}
+
+func (f *fakeMonitoredPlugin) Done() <-chan error {
+	return f.done
+}
+
+func (f *fakeMonitoredPlugin) GetPluginInfo() (workspace.PluginInfo, error) {
+	f.infoCalls++
+	return workspace.PluginInfo{}, f.infoErr
+}
+
+func newTestSupervisor() *pluginSupervisor {
+	return &pluginSupervisor{status: PluginStatusRunning, stop: make(chan struct{})}
+}
+
+func TestWatchReturnsSubprocessExitError(t *testing.T) {
+	sup := newTestSupervisor()
+	plug := newFakeMonitoredPlugin()
+	plug.done <- assert.AnError
+
+	err := sup.watch(plug, time.Hour)
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestWatchReturnsLivenessCheckFailure(t *testing.T) {
+	sup := newTestSupervisor()
+	plug := newFakeMonitoredPlugin()
+	plug.infoErr = assert.AnError
+
+	err := sup.watch(plug, time.Millisecond)
+	assert.Equal(t, assert.AnError, err)
+	assert.GreaterOrEqual(t, plug.infoCalls, 1)
+}
+
+func TestWatchReturnsStoppedErrorWhenSupervisorStops(t *testing.T) {
+	sup := newTestSupervisor()
+	plug := newFakeMonitoredPlugin()
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(sup.stop)
+	}()
+
+	err := sup.watch(plug, time.Hour)
+	assert.Equal(t, errSupervisorStopped, err)
+}
+
+func TestPluginSupervisorStatusTransitions(t *testing.T) {
+	sup := newTestSupervisor()
+	assert.Equal(t, PluginStatusRunning, sup.getStatus())
+
+	sup.setStatus(PluginStatusRestarting)
+	assert.Equal(t, PluginStatusRestarting, sup.getStatus())
+
+	sup.setStatus(PluginStatusCrashed)
+	assert.Equal(t, PluginStatusCrashed, sup.getStatus())
+}
+
+func TestPluginStatusString(t *testing.T) {
+	assert.Equal(t, "unknown", PluginStatusUnknown.String())
+	assert.Equal(t, "running", PluginStatusRunning.String())
+	assert.Equal(t, "restarting", PluginStatusRestarting.String())
+	assert.Equal(t, "crashed", PluginStatusCrashed.String())
+}
@@ -0,0 +1,54 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// LogRecord is a single structured log entry emitted by a plugin.  It carries everything the thin,
+// severity-and-message Log RPC discards: which plugin produced it, when, and any structured fields the
+// plugin chose to attach.
+type LogRecord struct {
+	// Plugin identifies the plugin that produced this record.  It is the zero value for records logged
+	// directly by the host rather than relayed from a plugin's RPC.
+	Plugin workspace.PluginInfo
+	// Time is when the record was produced.
+	Time time.Time
+	// Sev is the record's severity.
+	Sev diag.Severity
+	// URN is the resource this record pertains to, if any.
+	URN resource.URN
+	// Message is the human-readable log message.
+	Message string
+	// Fields carries arbitrary structured data attached by the plugin (e.g. request IDs, durations).
+	Fields map[string]interface{}
+	// Ephemeral is true for transient progress output -- e.g. a percentage counter -- that a sink may choose
+	// to display without persisting.
+	Ephemeral bool
+}
+
+// LogSink receives structured log records from plugins, in addition to the diagnostics surfaced through
+// Host.Log.  Implementations can route provider logs to per-plugin files, JSON streams, or an observability
+// backend without polluting user-facing diagnostics.
+type LogSink interface {
+	// Log is called once per structured record.  It must not block for long, since it is called on the
+	// goroutine handling the plugin's RPC.
+	Log(record LogRecord)
+}
@@ -0,0 +1,78 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+type fakeLogSink struct {
+	records []LogRecord
+}
+
+func (f *fakeLogSink) Log(record LogRecord) {
+	f.records = append(f.records, record)
+}
+
+// fakeDiagSink is a no-op diag.Sink, so OnPluginLog can be exercised without a real diagnostics backend.
+type fakeDiagSink struct{}
+
+func (fakeDiagSink) Logf(sev diag.Severity, msg diag.Message) {}
+
+func TestDispatchToLogSinkIsNoOpWithoutASink(t *testing.T) {
+	assert.NotPanics(t, func() {
+		dispatchToLogSink(nil, LogRecord{Message: "hello"})
+	})
+}
+
+func TestDispatchToLogSinkForwardsTheRecordVerbatim(t *testing.T) {
+	sink := &fakeLogSink{}
+	record := LogRecord{
+		Message:   "uploading asset",
+		Fields:    map[string]interface{}{"bytes": 1024},
+		Ephemeral: true,
+	}
+
+	dispatchToLogSink(sink, record)
+
+	assert.Len(t, sink.records, 1)
+	assert.Equal(t, record, sink.records[0])
+}
+
+func TestOnPluginLogStampsPluginFieldsAndEphemeralFromTheRPC(t *testing.T) {
+	sink := &fakeLogSink{}
+	host := &defaultHost{
+		ctx:     &Context{Diag: fakeDiagSink{}},
+		logSink: sink,
+	}
+	plug := workspace.PluginInfo{Name: "aws", Kind: workspace.ResourcePlugin}
+	fields := map[string]interface{}{"requestID": "abc123"}
+
+	host.OnPluginLog(plug, diag.Warning, "", "rate limited, retrying", fields, true)
+
+	assert.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, plug, record.Plugin)
+	assert.Equal(t, diag.Warning, record.Sev)
+	assert.Equal(t, "rate limited, retrying", record.Message)
+	assert.Equal(t, fields, record.Fields)
+	assert.True(t, record.Ephemeral)
+	assert.False(t, record.Time.IsZero())
+}
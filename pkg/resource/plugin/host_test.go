@@ -0,0 +1,78 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func mustVersion(t *testing.T, v string) *semver.Version {
+	parsed, err := semver.ParseTolerant(v)
+	assert.NoError(t, err)
+	return &parsed
+}
+
+func TestSelectBestCandidatePicksHighestSatisfyingVersion(t *testing.T) {
+	aws := mustVersion(t, "1.2.0")
+	awsNewer := mustVersion(t, "1.5.0")
+	other := mustVersion(t, "9.9.9")
+
+	installed := []workspace.PluginInfo{
+		{Name: "aws", Kind: workspace.ResourcePlugin, Version: aws},
+		{Name: "aws", Kind: workspace.ResourcePlugin, Version: awsNewer},
+		{Name: "aws", Kind: workspace.AnalyzerPlugin, Version: other},   // wrong kind, ignored
+		{Name: "azure", Kind: workspace.ResourcePlugin, Version: other}, // wrong name, ignored
+	}
+
+	r, err := semver.ParseRange(">=1.0.0 <2.0.0")
+	assert.NoError(t, err)
+
+	best := selectBestCandidate(installed, tokens.Package("aws"), r)
+	assert.NotNil(t, best)
+	assert.True(t, best.EQ(*awsNewer))
+}
+
+func TestSelectBestCandidateReturnsNilWhenNothingSatisfies(t *testing.T) {
+	installed := []workspace.PluginInfo{
+		{Name: "aws", Kind: workspace.ResourcePlugin, Version: mustVersion(t, "1.2.0")},
+	}
+
+	r, err := semver.ParseRange(">=2.0.0")
+	assert.NoError(t, err)
+
+	assert.Nil(t, selectBestCandidate(installed, tokens.Package("aws"), r))
+}
+
+func TestRequestVersionKey(t *testing.T) {
+	assert.Equal(t, "", requestVersionKey(nil, nil))
+	assert.Equal(t, "1.2.0", requestVersionKey(mustVersion(t, "1.2.0"), nil))
+
+	r, err := semver.ParseRange(">=1.0.0 <2.0.0")
+	assert.NoError(t, err)
+
+	// The same *Range value coalesces onto the same key...
+	assert.Equal(t, requestVersionKey(nil, &r), requestVersionKey(nil, &r))
+
+	// ...but a distinct Range value, even one parsed from the same string, does not.
+	r2, err := semver.ParseRange(">=1.0.0 <2.0.0")
+	assert.NoError(t, err)
+	assert.NotEqual(t, requestVersionKey(nil, &r), requestVersionKey(nil, &r2))
+}
@@ -0,0 +1,89 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// defaultLoaderParallelism is the number of plugin subprocesses that may be started concurrently when the
+// host is not configured with a different limit via HostOptions.LoaderParallelism.
+const defaultLoaderParallelism = 4
+
+// pluginKey uniquely identifies a plugin load request, so that concurrent callers asking for the very same
+// (kind, name, version) coalesce onto a single in-flight load rather than forking a redundant subprocess.
+type pluginKey struct {
+	kind    workspace.PluginKind
+	name    string
+	version string
+}
+
+// pluginLoad tracks a single in-flight or completed plugin load.  Callers that request the same pluginKey
+// while a load is already underway block on done rather than starting a second one.
+type pluginLoad struct {
+	done   chan struct{}
+	plugin interface{}
+	err    error
+}
+
+// pluginLoader coalesces concurrent requests for the same plugin (so we never fork two subprocesses for one
+// logical plugin) while still letting independent plugins load in parallel, up to a bounded pool size.
+type pluginLoader struct {
+	sem chan struct{} // bounds the number of "load" funcs that may be running at once.
+
+	mu       sync.Mutex
+	inflight map[pluginKey]*pluginLoad
+}
+
+// newPluginLoader creates a loader that permits up to parallelism concurrent plugin loads.  A non-positive
+// parallelism falls back to defaultLoaderParallelism.
+func newPluginLoader(parallelism int) *pluginLoader {
+	if parallelism <= 0 {
+		parallelism = defaultLoaderParallelism
+	}
+	return &pluginLoader{
+		sem:      make(chan struct{}, parallelism),
+		inflight: make(map[pluginKey]*pluginLoad),
+	}
+}
+
+// load runs load, ensuring that at most one call for a given key is ever in flight -- concurrent callers for
+// the same key coalesce onto the first caller's result -- and that no more than the loader's configured
+// parallelism run concurrently across all keys.
+func (l *pluginLoader) load(key pluginKey, load func() (interface{}, error)) (interface{}, error) {
+	l.mu.Lock()
+	if existing, has := l.inflight[key]; has {
+		l.mu.Unlock()
+		<-existing.done
+		return existing.plugin, existing.err
+	}
+
+	pl := &pluginLoad{done: make(chan struct{})}
+	l.inflight[key] = pl
+	l.mu.Unlock()
+
+	l.sem <- struct{}{}
+	pl.plugin, pl.err = load()
+	<-l.sem
+
+	l.mu.Lock()
+	delete(l.inflight, key)
+	close(pl.done)
+	l.mu.Unlock()
+
+	return pl.plugin, pl.err
+}
@@ -0,0 +1,92 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginLoaderCoalescesConcurrentRequestsForSameKey(t *testing.T) {
+	l := newPluginLoader(4)
+	key := pluginKey{name: "aws"}
+
+	var starts int32
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := 0; i < 8; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plug, err := l.load(key, func() (interface{}, error) {
+				atomic.AddInt32(&starts, 1)
+				time.Sleep(10 * time.Millisecond)
+				return "loaded", nil
+			})
+			assert.NoError(t, err)
+			results[i] = plug
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, starts, "all concurrent callers for the same key should coalesce onto one load")
+	for _, r := range results {
+		assert.Equal(t, "loaded", r)
+	}
+}
+
+func TestPluginLoaderBoundsConcurrentLoads(t *testing.T) {
+	const parallelism = 2
+	l := newPluginLoader(parallelism)
+
+	var running int32
+	var maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		key := pluginKey{name: string(rune('a' + i))}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = l.load(key, func() (interface{}, error) {
+				cur := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if cur <= max || atomic.CompareAndSwapInt32(&maxRunning, max, cur) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, int(maxRunning), parallelism, "loader should never run more than its configured parallelism concurrently")
+}
+
+func TestNewPluginLoaderFallsBackOnNonPositiveParallelism(t *testing.T) {
+	l := newPluginLoader(0)
+	assert.Equal(t, defaultLoaderParallelism, cap(l.sem))
+
+	l = newPluginLoader(-3)
+	assert.Equal(t, defaultLoaderParallelism, cap(l.sem))
+}
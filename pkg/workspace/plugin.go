@@ -0,0 +1,126 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// PluginKind represents the kind of a plugin that may be dynamically loaded and used by Pulumi.
+type PluginKind string
+
+const (
+	// AnalyzerPlugin is a plugin that can be used as a resource analyzer.
+	AnalyzerPlugin PluginKind = "analyzer"
+	// LanguagePlugin is a plugin that can be used as a language host.
+	LanguagePlugin PluginKind = "language"
+	// ResourcePlugin is a plugin that can be used as a resource provider for custom CRUD operations.
+	ResourcePlugin PluginKind = "resource"
+)
+
+// PluginInfo describes a single plugin: an installed plugin as discovered on disk by GetPlugins, a loaded
+// plugin, or a request for one (see VersionRange).
+type PluginInfo struct {
+	// Name is the simple name of the plugin.
+	Name string
+	// Path is the plugin's location on disk; for a plugin found by GetPlugins, it is the install directory.
+	Path string
+	// Kind is the kind of plugin this is (analyzer, language, or resource).
+	Kind PluginKind
+	// Version is the plugin's version, if it could be determined from its install path.
+	Version *semver.Version
+	// VersionRange is set instead of Version on a PluginInfo that describes a request for a plugin rather
+	// than an installed binary -- e.g. the dependencies returned by a language host's GetRequiredPlugins, or
+	// the argument to EnsurePlugins -- and is resolved against installed versions by the caller.
+	VersionRange *semver.Range
+}
+
+// installDir is the directory beneath the Pulumi home directory where plugin binaries are installed, one
+// subdirectory per (kind, name, version) triple, following the convention "<kind>-<name>-v<version>".
+const installDir = "plugins"
+
+// GetPlugins returns all plugins currently installed under the Pulumi home directory, across every kind.
+func GetPlugins() ([]PluginInfo, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving user home directory")
+	}
+
+	dir := filepath.Join(home, ".pulumi", installDir)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading plugin directory %s", dir)
+	}
+
+	var plugins []PluginInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		plugin, ok := parsePluginDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		plugin.Path = filepath.Join(dir, entry.Name())
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+// parsePluginDirName parses a plugin install directory name of the form "<kind>-<name>-v<version>" into a
+// PluginInfo.  name itself may contain hyphens (e.g. "resource-aws-native-v1.0.0"), so the version is split
+// off from the right -- at the last "-v<semver>" -- rather than assumed to be the directory name's third
+// dash-delimited component.  It returns false if name doesn't match that convention.
+func parsePluginDirName(name string) (PluginInfo, bool) {
+	kindSep := strings.Index(name, "-")
+	if kindSep < 0 {
+		return PluginInfo{}, false
+	}
+
+	kind := PluginKind(name[:kindSep])
+	switch kind {
+	case AnalyzerPlugin, LanguagePlugin, ResourcePlugin:
+		// ok
+	default:
+		return PluginInfo{}, false
+	}
+
+	rest := name[kindSep+1:]
+	versionSep := strings.LastIndex(rest, "-v")
+	if versionSep < 0 {
+		return PluginInfo{}, false
+	}
+
+	pluginName, versionStr := rest[:versionSep], rest[versionSep+len("-v"):]
+	if pluginName == "" {
+		return PluginInfo{}, false
+	}
+
+	version, err := semver.ParseTolerant(versionStr)
+	if err != nil {
+		return PluginInfo{}, false
+	}
+
+	return PluginInfo{Name: pluginName, Kind: kind, Version: &version}, true
+}
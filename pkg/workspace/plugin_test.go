@@ -0,0 +1,57 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePluginDirNameParsesASimpleName(t *testing.T) {
+	plugin, ok := parsePluginDirName("resource-aws-v1.2.3")
+	assert.True(t, ok)
+	assert.Equal(t, ResourcePlugin, plugin.Kind)
+	assert.Equal(t, "aws", plugin.Name)
+	assert.Equal(t, "1.2.3", plugin.Version.String())
+}
+
+func TestParsePluginDirNameParsesAHyphenatedName(t *testing.T) {
+	plugin, ok := parsePluginDirName("resource-aws-native-v1.0.0")
+	assert.True(t, ok)
+	assert.Equal(t, ResourcePlugin, plugin.Kind)
+	assert.Equal(t, "aws-native", plugin.Name)
+	assert.Equal(t, "1.0.0", plugin.Version.String())
+
+	plugin, ok = parsePluginDirName("resource-google-native-v1.0.0")
+	assert.True(t, ok)
+	assert.Equal(t, "google-native", plugin.Name)
+	assert.Equal(t, "1.0.0", plugin.Version.String())
+}
+
+func TestParsePluginDirNameRejectsUnknownKind(t *testing.T) {
+	_, ok := parsePluginDirName("bogus-aws-v1.2.3")
+	assert.False(t, ok)
+}
+
+func TestParsePluginDirNameRejectsMissingVersion(t *testing.T) {
+	_, ok := parsePluginDirName("resource-aws")
+	assert.False(t, ok)
+}
+
+func TestParsePluginDirNameRejectsUnparseableVersion(t *testing.T) {
+	_, ok := parsePluginDirName("resource-aws-vnotasemver")
+	assert.False(t, ok)
+}